@@ -6,7 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -14,11 +18,80 @@ const (
 	baseURL = "https://api.luadns.com/v1"
 )
 
+// RetryConfig controls how Client.doRequest retries transient failures
+// (network errors and HTTP 429/500/502/503/504 responses).
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// A value <= 1 disables retries.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	// BaseDelay is the backoff before the first retry.
+	BaseDelay time.Duration `json:"base_delay,omitempty"`
+
+	// MaxDelay caps the backoff between retries.
+	MaxDelay time.Duration `json:"max_delay,omitempty"`
+
+	// Factor is the exponential growth rate applied to BaseDelay after
+	// each attempt.
+	Factor float64 `json:"factor,omitempty"`
+}
+
+// DefaultRetryConfig is the retry policy used by clients created with
+// NewClient.
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Factor:      2,
+}
+
+// RateLimit configures a token-bucket limiter shared across all requests
+// made by a Client, so that many certificates provisioned in parallel don't
+// exceed LuaDNS's rate cap. QPS <= 0 disables limiting.
+type RateLimit struct {
+	QPS   float64 `json:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+}
+
 // Client is an HTTP client for the Lua DNS API
 type Client struct {
 	email      string
 	apiKey     string
 	httpClient *http.Client
+
+	// Retry is the retry policy applied to every request. Set MaxAttempts
+	// to 1 to disable retries.
+	Retry RetryConfig
+
+	// RateLimit caps the rate of outgoing requests. It is disabled by
+	// default; set QPS > 0 to enable it.
+	RateLimit RateLimit
+
+	limiterMu     sync.Mutex
+	limiterTokens float64
+	limiterInit   bool
+	limiterLast   time.Time
+
+	// OnRequest, if set, is called immediately before each HTTP request is
+	// sent to the Lua DNS API.
+	OnRequest func(method, path string)
+
+	// OnResponse, if set, is called after each HTTP request completes
+	// (successfully or not), so operators can observe API latency and
+	// error rates without forking the module.
+	OnResponse func(method, path string, statusCode int, duration time.Duration, err error)
+}
+
+// ClientOption configures a Client constructed by NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient overrides the *http.Client used to talk to the Lua DNS API,
+// e.g. to route through a corporate proxy, configure mTLS, or wrap the
+// transport with instrumentation.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
 }
 
 // Zone represents a DNS zone in Lua DNS
@@ -41,6 +114,10 @@ type Record struct {
 type APIError struct {
 	StatusCode int
 	Message    string
+
+	// RetryAfter is the server-requested backoff parsed from a Retry-After
+	// header, if one was present (most commonly on a 429 response).
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -48,25 +125,102 @@ func (e *APIError) Error() string {
 }
 
 // NewClient creates a new Lua DNS API client
-func NewClient(email, apiKey string) *Client {
-	return &Client{
+func NewClient(email, apiKey string, opts ...ClientOption) *Client {
+	c := &Client{
 		email:  email,
 		apiKey: apiKey,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		Retry: DefaultRetryConfig,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
-// doRequest performs an HTTP request with authentication
+// doRequest performs an HTTP request with authentication, retrying
+// transient failures with exponential backoff and jitter and honouring the
+// Client's rate limit, if any.
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewReader(jsonData)
+	}
+
+	attempts := c.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err := c.awaitRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.doOnce(ctx, method, path, jsonBody)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if attempt == attempts-1 {
+			break
+		}
+
+		delay, retryable := retryDelay(method, err, c.Retry, attempt)
+		if !retryable {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil, lastErr
+}
+
+// doOnce performs a single attempt at the request, returning an *APIError
+// for non-2xx responses, and reports the attempt via OnRequest/OnResponse.
+func (c *Client) doOnce(ctx context.Context, method, path string, jsonBody []byte) (*http.Response, error) {
+	if c.OnRequest != nil {
+		c.OnRequest(method, path)
+	}
+
+	start := time.Now()
+	resp, err := c.doHTTP(ctx, method, path, jsonBody)
+
+	if c.OnResponse != nil {
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+		} else if apiErr, ok := err.(*APIError); ok {
+			statusCode = apiErr.StatusCode
+		}
+		c.OnResponse(method, path, statusCode, time.Since(start), err)
+	}
+
+	return resp, err
+}
+
+// doHTTP builds and sends a single HTTP request.
+func (c *Client) doHTTP(ctx context.Context, method, path string, jsonBody []byte) (*http.Response, error) {
+	var reqBody io.Reader
+	if jsonBody != nil {
+		reqBody = bytes.NewReader(jsonBody)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, reqBody)
@@ -79,7 +233,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 
 	// Set headers
 	req.Header.Set("Accept", "application/json")
-	if body != nil {
+	if jsonBody != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
@@ -95,12 +249,121 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		return nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    string(bodyBytes),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
 		}
 	}
 
 	return resp, nil
 }
 
+// retryDelay reports whether err is worth retrying and, if so, how long to
+// wait first: the API's Retry-After when present, otherwise an exponential
+// backoff with jitter. POST requests are only retried on a 429: unlike
+// GET/PUT/DELETE, a POST (CreateZone, CreateRecord, ImportRecords, ...)
+// isn't idempotent, so retrying one on an ambiguous 5xx or network error
+// risks creating a duplicate record or zone that actually succeeded
+// server-side. A 429 is different — the server rejected the request before
+// processing it, so it's always safe to retry regardless of method.
+func retryDelay(method string, err error, cfg RetryConfig, attempt int) (time.Duration, bool) {
+	apiErr, ok := err.(*APIError)
+	if method == http.MethodPost && !(ok && apiErr.StatusCode == http.StatusTooManyRequests) {
+		return 0, false
+	}
+
+	if ok {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError,
+			http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if apiErr.RetryAfter > 0 {
+				return apiErr.RetryAfter, true
+			}
+		default:
+			return 0, false
+		}
+	}
+	// Non-APIError failures are network/transport errors, which are retried too.
+
+	base := cfg.BaseDelay
+	if base <= 0 {
+		base = DefaultRetryConfig.BaseDelay
+	}
+	factor := cfg.Factor
+	if factor <= 0 {
+		factor = DefaultRetryConfig.Factor
+	}
+	maxDelay := cfg.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryConfig.MaxDelay
+	}
+
+	delay := time.Duration(float64(base) * math.Pow(factor, float64(attempt)))
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	// Full jitter: a random delay between 0 and the computed backoff.
+	delay = time.Duration(rand.Int63n(int64(delay) + 1))
+
+	return delay, true
+}
+
+// parseRetryAfter parses an HTTP Retry-After header expressed in seconds.
+// HTTP-date values aren't used by LuaDNS and are ignored.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// awaitRateLimit blocks until a token is available under c.RateLimit, or
+// until ctx is done.
+func (c *Client) awaitRateLimit(ctx context.Context) error {
+	if c.RateLimit.QPS <= 0 {
+		return nil
+	}
+
+	// A Burst <= 0 would make every refill clamp to 0, so the bucket could
+	// never hold a token and every request would block until ctx is done.
+	// Treat it as "at least one in flight" instead of a hard stop.
+	burst := c.RateLimit.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	for {
+		c.limiterMu.Lock()
+		now := time.Now()
+		if !c.limiterInit {
+			c.limiterTokens = float64(burst)
+			c.limiterLast = now
+			c.limiterInit = true
+		}
+		elapsed := now.Sub(c.limiterLast).Seconds()
+		c.limiterLast = now
+		c.limiterTokens = math.Min(float64(burst), c.limiterTokens+elapsed*c.RateLimit.QPS)
+
+		if c.limiterTokens >= 1 {
+			c.limiterTokens--
+			c.limiterMu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - c.limiterTokens) / c.RateLimit.QPS * float64(time.Second))
+		c.limiterMu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
 // ListZones retrieves all zones from the Lua DNS API
 func (c *Client) ListZones(ctx context.Context) ([]Zone, error) {
 	resp, err := c.doRequest(ctx, http.MethodGet, "/zones", nil)
@@ -117,6 +380,58 @@ func (c *Client) ListZones(ctx context.Context) ([]Zone, error) {
 	return zones, nil
 }
 
+// CreateZone creates a new zone in the Lua DNS account
+func (c *Client) CreateZone(ctx context.Context, name string) (Zone, error) {
+	resp, err := c.doRequest(ctx, http.MethodPost, "/zones", Zone{Name: name})
+	if err != nil {
+		return Zone{}, err
+	}
+	defer resp.Body.Close()
+
+	var created Zone
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return Zone{}, fmt.Errorf("failed to decode create zone response: %w", err)
+	}
+
+	return created, nil
+}
+
+// DeleteZone deletes a zone from the Lua DNS account
+func (c *Client) DeleteZone(ctx context.Context, zoneID int) error {
+	path := fmt.Sprintf("/zones/%d", zoneID)
+	resp, err := c.doRequest(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// zoneImportRequest is the body LuaDNS's bulk import endpoint expects.
+type zoneImportRequest struct {
+	Content string `json:"content"`
+	Replace bool   `json:"replace"`
+}
+
+// ImportRecords bulk-loads content, a BIND-format zone file, into a zone
+// through LuaDNS's documented /zones/{id}/records/import endpoint. When
+// replace is true the import replaces the zone's existing records entirely;
+// otherwise it's merged with what's already there. LuaDNS doesn't expose
+// server-side Lua script evaluation over its public API, so this is the
+// closest supported primitive to "zone as code": the caller is responsible
+// for rendering a Lua zone script to its zone-file output before calling this.
+func (c *Client) ImportRecords(ctx context.Context, zoneID int, content string, replace bool) error {
+	path := fmt.Sprintf("/zones/%d/records/import", zoneID)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, zoneImportRequest{Content: content, Replace: replace})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
 // ListRecords retrieves all records for a zone
 func (c *Client) ListRecords(ctx context.Context, zoneID int) ([]Record, error) {
 	path := fmt.Sprintf("/zones/%d/records", zoneID)
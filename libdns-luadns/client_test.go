@@ -0,0 +1,138 @@
+package luadns
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelayNeverRetriesPostOnAmbiguousFailure(t *testing.T) {
+	apiErr := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	if _, retryable := retryDelay(http.MethodPost, apiErr, DefaultRetryConfig, 0); retryable {
+		t.Error("expected POST not to be retried for a 503, which may have been processed server-side")
+	}
+
+	if _, retryable := retryDelay(http.MethodPost, errors.New("connection reset"), DefaultRetryConfig, 0); retryable {
+		t.Error("expected POST not to be retried for a network error")
+	}
+}
+
+func TestRetryDelayRetriesPostOn429(t *testing.T) {
+	apiErr := &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second}
+
+	delay, retryable := retryDelay(http.MethodPost, apiErr, DefaultRetryConfig, 0)
+	if !retryable {
+		t.Error("expected POST to be retried on 429: the server rejected it before processing")
+	}
+	if delay != time.Second {
+		t.Errorf("delay = %v, want 1s from Retry-After", delay)
+	}
+}
+
+func TestRetryDelayRetriesIdempotentMethods(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		apiErr := &APIError{StatusCode: http.StatusServiceUnavailable}
+		if _, retryable := retryDelay(method, apiErr, DefaultRetryConfig, 0); !retryable {
+			t.Errorf("%s: expected a 503 to be retryable", method)
+		}
+	}
+}
+
+func TestRetryDelayHonoursRetryAfter(t *testing.T) {
+	apiErr := &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second}
+
+	delay, retryable := retryDelay(http.MethodGet, apiErr, DefaultRetryConfig, 0)
+	if !retryable {
+		t.Fatal("expected 429 to be retryable")
+	}
+	if delay != 7*time.Second {
+		t.Errorf("delay = %v, want 7s from Retry-After", delay)
+	}
+}
+
+func TestRetryDelayNotRetryableStatus(t *testing.T) {
+	apiErr := &APIError{StatusCode: http.StatusNotFound}
+
+	if _, retryable := retryDelay(http.MethodGet, apiErr, DefaultRetryConfig, 0); retryable {
+		t.Error("expected a 404 not to be retried")
+	}
+}
+
+func TestRetryDelayBackoffCapped(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 2 * time.Second, Factor: 10}
+
+	delay, retryable := retryDelay(http.MethodGet, errors.New("network error"), cfg, 3)
+	if !retryable {
+		t.Fatal("expected network errors to be retryable")
+	}
+	if delay > cfg.MaxDelay {
+		t.Errorf("delay = %v, want capped at %v", delay, cfg.MaxDelay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    0,
+		"5":   5 * time.Second,
+		"0":   0,
+		"-1":  0,
+		"abc": 0,
+	}
+	for header, want := range tests {
+		if got := parseRetryAfter(header); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestAwaitRateLimit(t *testing.T) {
+	c := &Client{RateLimit: RateLimit{QPS: 1000, Burst: 1}}
+
+	ctx := context.Background()
+	if err := c.awaitRateLimit(ctx); err != nil {
+		t.Fatalf("first token: %v", err)
+	}
+
+	start := time.Now()
+	if err := c.awaitRateLimit(ctx); err != nil {
+		t.Fatalf("second token: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed <= 0 {
+		t.Error("expected awaitRateLimit to block once the burst is exhausted")
+	}
+}
+
+func TestAwaitRateLimitZeroBurstStillGrantsTokens(t *testing.T) {
+	c := &Client{RateLimit: RateLimit{QPS: 1000, Burst: 0}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := c.awaitRateLimit(ctx); err != nil {
+		t.Fatalf("awaitRateLimit with Burst: 0 should not deadlock, got %v", err)
+	}
+}
+
+func TestAwaitRateLimitDisabled(t *testing.T) {
+	c := &Client{}
+	if err := c.awaitRateLimit(context.Background()); err != nil {
+		t.Fatalf("expected no-op when QPS is unset, got %v", err)
+	}
+}
+
+func TestAwaitRateLimitContextCanceled(t *testing.T) {
+	c := &Client{RateLimit: RateLimit{QPS: 0.001, Burst: 1}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := c.awaitRateLimit(ctx); err != nil {
+		t.Fatalf("first token: %v", err)
+	}
+	cancel()
+
+	if err := c.awaitRateLimit(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("awaitRateLimit after cancel = %v, want context.Canceled", err)
+	}
+}
@@ -5,6 +5,9 @@ package luadns
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +23,25 @@ type Provider struct {
 	// APIKey is your Lua DNS API key from https://www.luadns.com/api_keys
 	APIKey string `json:"api_key,omitempty"`
 
+	// Retry overrides the client's retry policy for transient failures.
+	// The zero value uses DefaultRetryConfig.
+	Retry RetryConfig `json:"retry,omitempty"`
+
+	// RateLimit caps the rate of requests issued to the Lua DNS API. It is
+	// disabled by default; set QPS > 0 to enable it.
+	RateLimit RateLimit `json:"rate_limit,omitempty"`
+
+	// HTTPClient, if set, is used instead of the default client to make API
+	// requests. Useful for corporate proxies, mTLS, or wrapping the
+	// transport with instrumentation.
+	HTTPClient *http.Client `json:"-"`
+
+	// OnRequest and OnResponse, when set, are wired to the underlying
+	// Client so callers can observe API latency and error rates without
+	// forking the module.
+	OnRequest  func(method, path string)                                                    `json:"-"`
+	OnResponse func(method, path string, statusCode int, duration time.Duration, err error) `json:"-"`
+
 	// client is the internal HTTP client for API communication
 	client *Client
 
@@ -46,7 +68,11 @@ func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record
 
 	records := make([]libdns.Record, 0, len(apiRecords))
 	for _, r := range apiRecords {
-		records = append(records, toLibdnsRecord(r, zone))
+		rec, err := toLibdnsRecord(r, zone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse record %s %s: %w", r.Name, r.Type, err)
+		}
+		records = append(records, rec)
 	}
 
 	return records, nil
@@ -65,21 +91,46 @@ func (p *Provider) AppendRecords(ctx context.Context, zone string, records []lib
 
 	var created []libdns.Record
 	for _, rec := range records {
-		apiRec := fromLibdnsRecord(rec, zone)
+		apiRec, err := fromLibdnsRecord(rec, zone)
+		if err != nil {
+			return created, fmt.Errorf("failed to convert record %s: %w", rec.RR().Name, err)
+		}
 
 		createdRec, err := p.client.CreateRecord(ctx, zoneID, apiRec)
 		if err != nil {
-			return created, fmt.Errorf("failed to create record %s: %w", rec.Name, err)
+			return created, fmt.Errorf("failed to create record %s: %w", rec.RR().Name, err)
 		}
 
-		created = append(created, toLibdnsRecord(createdRec, zone))
+		newRec, err := toLibdnsRecord(createdRec, zone)
+		if err != nil {
+			return created, fmt.Errorf("failed to parse created record %s: %w", createdRec.Name, err)
+		}
+		created = append(created, newRec)
 	}
 
 	return created, nil
 }
 
-// SetRecords sets the records in the zone, either by updating existing records or creating new ones.
-// It returns the updated records.
+// rrset identifies a set of records sharing a name and type, e.g. all the
+// TXT records at _acme-challenge.example.com.
+type rrset struct {
+	name string
+	typ  string
+}
+
+// rrsetOf returns the rrset a record belongs to, with its name normalized to
+// the FQDN LuaDNS uses.
+func rrsetOf(rr libdns.RR, zone string) rrset {
+	return rrset{name: fqdn(rr.Name, zone), typ: strings.ToUpper(rr.Type)}
+}
+
+// SetRecords sets the records in the zone. Input records are grouped into
+// RRsets by (name, type) and each RRset is reconciled independently against
+// the matching LuaDNS records: existing records are updated in place where
+// possible, extra desired records are created, and existing records no
+// longer present in the desired set are deleted. This preserves multi-value
+// RRsets (e.g. several _acme-challenge TXT records) instead of collapsing
+// them onto a single record. It returns the updated records.
 func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	if err := p.ensureClient(); err != nil {
 		return nil, err
@@ -90,46 +141,86 @@ func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns
 		return nil, err
 	}
 
-	// Get existing records to find matches
 	existingRecords, err := p.client.ListRecords(ctx, zoneID)
 	if err != nil {
 		return nil, err
 	}
 
-	var updated []libdns.Record
+	// Group the desired records into RRsets, preserving input order.
+	var order []rrset
+	desired := make(map[rrset][]libdns.Record)
 	for _, rec := range records {
-		// Try to find existing record with same name and type
-		var existingID int
+		key := rrsetOf(rec.RR(), zone)
+		if _, ok := desired[key]; !ok {
+			order = append(order, key)
+		}
+		desired[key] = append(desired[key], rec)
+	}
+
+	var updated []libdns.Record
+	for _, key := range order {
+		var existingGroup []Record
 		for _, existing := range existingRecords {
-			if matchesRecord(existing, rec, zone) {
-				existingID = existing.ID
-				break
+			if existing.Name == strings.TrimSuffix(key.name, ".") && strings.EqualFold(existing.Type, key.typ) {
+				existingGroup = append(existingGroup, existing)
 			}
 		}
 
-		apiRec := fromLibdnsRecord(rec, zone)
-
-		if existingID > 0 {
-			// Update existing record
-			updatedRec, err := p.client.UpdateRecord(ctx, zoneID, existingID, apiRec)
+		used := make(map[int]bool, len(existingGroup))
+		for _, rec := range desired[key] {
+			apiRec, err := fromLibdnsRecord(rec, zone)
 			if err != nil {
-				return updated, fmt.Errorf("failed to update record %s: %w", rec.Name, err)
+				return updated, fmt.Errorf("failed to convert record %s: %w", rec.RR().Name, err)
 			}
-			updated = append(updated, toLibdnsRecord(updatedRec, zone))
-		} else {
-			// Create new record
-			createdRec, err := p.client.CreateRecord(ctx, zoneID, apiRec)
+
+			var target *Record
+			for i := range existingGroup {
+				if !used[existingGroup[i].ID] {
+					target = &existingGroup[i]
+					break
+				}
+			}
+
+			var resultRec Record
+			if target != nil {
+				used[target.ID] = true
+				resultRec, err = p.client.UpdateRecord(ctx, zoneID, target.ID, apiRec)
+				if err != nil {
+					return updated, fmt.Errorf("failed to update record %s: %w", rec.RR().Name, err)
+				}
+			} else {
+				resultRec, err = p.client.CreateRecord(ctx, zoneID, apiRec)
+				if err != nil {
+					return updated, fmt.Errorf("failed to create record %s: %w", rec.RR().Name, err)
+				}
+			}
+
+			newRec, err := toLibdnsRecord(resultRec, zone)
 			if err != nil {
-				return updated, fmt.Errorf("failed to create record %s: %w", rec.Name, err)
+				return updated, fmt.Errorf("failed to parse record %s: %w", resultRec.Name, err)
+			}
+			updated = append(updated, newRec)
+		}
+
+		// Remove existing records in this RRset that weren't reused above.
+		for _, existing := range existingGroup {
+			if used[existing.ID] {
+				continue
+			}
+			if err := p.client.DeleteRecord(ctx, zoneID, existing.ID); err != nil {
+				return updated, fmt.Errorf("failed to delete stale record %s: %w", existing.Name, err)
 			}
-			updated = append(updated, toLibdnsRecord(createdRec, zone))
 		}
 	}
 
 	return updated, nil
 }
 
-// DeleteRecords deletes the records from the zone. It returns the records that were deleted.
+// DeleteRecords deletes the records from the zone. When a record carries a
+// value (e.g. the Content/Text/Target of a TXT, MX, etc.), only the matching
+// record within the (name, type) RRset is removed, so callers can delete one
+// _acme-challenge TXT among several without wiping the rest. It returns the
+// records that were deleted.
 func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
 	if err := p.ensureClient(); err != nil {
 		return nil, err
@@ -148,13 +239,26 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 
 	var deleted []libdns.Record
 	for _, rec := range records {
-		// Find the record ID
+		rr := rec.RR()
+
+		apiRec, err := fromLibdnsRecord(rec, zone)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to convert record %s: %w", rr.Name, err)
+		}
+
+		// Find the record ID, additionally matching Content when the
+		// caller supplied a value so a specific record in a multi-value
+		// RRset can be targeted.
 		var recordID int
 		for _, existing := range existingRecords {
-			if matchesRecord(existing, rec, zone) {
-				recordID = existing.ID
-				break
+			if !matchesRecord(existing, rr, zone) {
+				continue
+			}
+			if apiRec.Content != "" && existing.Content != apiRec.Content {
+				continue
 			}
+			recordID = existing.ID
+			break
 		}
 
 		if recordID == 0 {
@@ -162,9 +266,9 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 			continue
 		}
 
-		err := p.client.DeleteRecord(ctx, zoneID, recordID)
+		err = p.client.DeleteRecord(ctx, zoneID, recordID)
 		if err != nil {
-			return deleted, fmt.Errorf("failed to delete record %s: %w", rec.Name, err)
+			return deleted, fmt.Errorf("failed to delete record %s: %w", rr.Name, err)
 		}
 
 		deleted = append(deleted, rec)
@@ -173,6 +277,106 @@ func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []lib
 	return deleted, nil
 }
 
+// ListZones lists the zones available to the configured Lua DNS account.
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	if err := p.ensureClient(); err != nil {
+		return nil, err
+	}
+
+	apiZones, err := p.client.ListZones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+
+	p.cacheZones(apiZones)
+
+	zones := make([]libdns.Zone, 0, len(apiZones))
+	for _, z := range apiZones {
+		zones = append(zones, libdns.Zone{Name: absoluteZoneName(z.Name)})
+	}
+
+	return zones, nil
+}
+
+// CreateZone creates a new zone under the configured Lua DNS account.
+func (p *Provider) CreateZone(ctx context.Context, zone string) (libdns.Zone, error) {
+	if err := p.ensureClient(); err != nil {
+		return libdns.Zone{}, err
+	}
+
+	created, err := p.client.CreateZone(ctx, strings.TrimSuffix(zone, "."))
+	if err != nil {
+		return libdns.Zone{}, fmt.Errorf("failed to create zone %s: %w", zone, err)
+	}
+
+	p.zoneCacheMu.Lock()
+	if p.zoneCache == nil {
+		p.zoneCache = make(map[string]int)
+	}
+	p.zoneCache[strings.TrimSuffix(created.Name, ".")] = created.ID
+	p.zoneCacheMu.Unlock()
+
+	return libdns.Zone{Name: absoluteZoneName(created.Name)}, nil
+}
+
+// DeleteZone deletes a zone from the configured Lua DNS account.
+func (p *Provider) DeleteZone(ctx context.Context, zone string) error {
+	if err := p.ensureClient(); err != nil {
+		return err
+	}
+
+	zoneID, err := p.getZoneID(ctx, zone)
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.DeleteZone(ctx, zoneID); err != nil {
+		return fmt.Errorf("failed to delete zone %s: %w", zone, err)
+	}
+
+	p.zoneCacheMu.Lock()
+	delete(p.zoneCache, strings.TrimSuffix(zone, "."))
+	p.zoneCacheMu.Unlock()
+
+	return nil
+}
+
+// ApplyZoneScript imports script, the rendered output of a LuaDNS Lua zone
+// script (a BIND-format zone file), into zone via LuaDNS's documented bulk
+// import endpoint, replacing the zone's existing records. LuaDNS doesn't run
+// Lua scripts server-side through its public API, so the caller is
+// responsible for evaluating the script and passing its zone-file output
+// here; this is what lets a zone's contents be declared as code alongside
+// records managed through AppendRecords/SetRecords.
+func (p *Provider) ApplyZoneScript(ctx context.Context, zone, script string) error {
+	if err := p.ensureClient(); err != nil {
+		return err
+	}
+
+	zoneID, err := p.getZoneID(ctx, zone)
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.ImportRecords(ctx, zoneID, script, true); err != nil {
+		return fmt.Errorf("failed to import zone script for %s: %w", zone, err)
+	}
+
+	return nil
+}
+
+// cacheZones replaces the cached zone name-to-ID mapping with apiZones.
+func (p *Provider) cacheZones(apiZones []Zone) {
+	cache := make(map[string]int, len(apiZones))
+	for _, z := range apiZones {
+		cache[strings.TrimSuffix(z.Name, ".")] = z.ID
+	}
+
+	p.zoneCacheMu.Lock()
+	p.zoneCache = cache
+	p.zoneCacheMu.Unlock()
+}
+
 // ensureClient creates the HTTP client if it doesn't exist
 func (p *Provider) ensureClient() error {
 	if p.Email == "" {
@@ -183,7 +387,18 @@ func (p *Provider) ensureClient() error {
 	}
 
 	if p.client == nil {
-		p.client = NewClient(p.Email, p.APIKey)
+		var opts []ClientOption
+		if p.HTTPClient != nil {
+			opts = append(opts, WithHTTPClient(p.HTTPClient))
+		}
+
+		p.client = NewClient(p.Email, p.APIKey, opts...)
+		if p.Retry != (RetryConfig{}) {
+			p.client.Retry = p.Retry
+		}
+		p.client.RateLimit = p.RateLimit
+		p.client.OnRequest = p.OnRequest
+		p.client.OnResponse = p.OnResponse
 	}
 
 	return nil
@@ -233,50 +448,243 @@ func (p *Provider) getZoneID(ctx context.Context, zone string) (int, error) {
 	return zoneID, nil
 }
 
-// toLibdnsRecord converts a Lua DNS API record to a libdns record
-func toLibdnsRecord(r Record, zone string) libdns.Record {
-	name := strings.TrimSuffix(r.Name, "."+zone)
-	name = strings.TrimSuffix(name, ".")
+// toLibdnsRecord converts a Lua DNS API record to a libdns record, parsing the
+// RDATA held in Content according to the record's type so that fields like MX
+// priority or SRV weight/port survive the round trip.
+func toLibdnsRecord(r Record, zone string) (libdns.Record, error) {
+	name := relativeName(r.Name, zone)
+	ttl := secondsToTTL(r.TTL)
+	fields := strings.Fields(r.Content)
+
+	switch strings.ToUpper(r.Type) {
+	case "A", "AAAA":
+		ip, err := netip.ParseAddr(r.Content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", r.Content, err)
+		}
+		return libdns.Address{Name: name, TTL: ttl, IP: ip}, nil
+
+	case "CNAME":
+		return libdns.CNAME{Name: name, TTL: ttl, Target: r.Content}, nil
+
+	case "NS":
+		return libdns.NS{Name: name, TTL: ttl, Target: r.Content}, nil
+
+	case "TXT":
+		return libdns.TXT{Name: name, TTL: ttl, Text: r.Content}, nil
+
+	case "MX":
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed MX content %q", r.Content)
+		}
+		pref, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MX preference %q: %w", fields[0], err)
+		}
+		return libdns.MX{Name: name, TTL: ttl, Preference: uint16(pref), Target: fields[1]}, nil
+
+	case "SRV":
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed SRV content %q", r.Content)
+		}
+		priority, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SRV priority %q: %w", fields[0], err)
+		}
+		weight, err := strconv.ParseUint(fields[1], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SRV weight %q: %w", fields[1], err)
+		}
+		port, err := strconv.ParseUint(fields[2], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SRV port %q: %w", fields[2], err)
+		}
+		service, transport, host := splitSRVName(name)
+		return libdns.SRV{
+			Service:   service,
+			Transport: transport,
+			Name:      host,
+			TTL:       ttl,
+			Priority:  uint16(priority),
+			Weight:    uint16(weight),
+			Port:      uint16(port),
+			Target:    fields[3],
+		}, nil
+
+	case "CAA":
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("malformed CAA content %q", r.Content)
+		}
+		flags, err := strconv.ParseUint(fields[0], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CAA flags %q: %w", fields[0], err)
+		}
+		value, err := strconv.Unquote(strings.Join(fields[2:], " "))
+		if err != nil {
+			value = strings.Trim(strings.Join(fields[2:], " "), `"`)
+		}
+		return libdns.CAA{
+			Name:  name,
+			TTL:   ttl,
+			Flags: uint8(flags),
+			Tag:   fields[1],
+			Value: value,
+		}, nil
+
+	case "HTTPS", "SVCB":
+		// ServiceBinding records encode their scheme/port into the DNS name
+		// itself (e.g. "_443._https.example.com"), so leave the parsing to
+		// libdns.RR.Parse rather than re-implementing it here.
+		rr := libdns.RR{Name: name, TTL: ttl, Type: strings.ToUpper(r.Type), Data: r.Content}
+		rec, err := rr.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("malformed %s content %q: %w", r.Type, r.Content, err)
+		}
+		return rec, nil
+
+	// TLSA and SSHFP have no dedicated type in libdns; fall back to the
+	// generic RR the default case below uses for unknown types.
+	case "TLSA", "SSHFP":
+		return libdns.RR{Name: name, TTL: ttl, Type: strings.ToUpper(r.Type), Data: r.Content}, nil
 
-	return libdns.Record{
-		ID:    fmt.Sprintf("%d", r.ID),
-		Type:  r.Type,
-		Name:  name,
-		Value: r.Content,
-		TTL:   time.Duration(r.TTL) * time.Second,
+	default:
+		return libdns.RR{Name: name, TTL: ttl, Type: r.Type, Data: r.Content}, nil
 	}
 }
 
-// fromLibdnsRecord converts a libdns record to a Lua DNS API record
-func fromLibdnsRecord(r libdns.Record, zone string) Record {
-	// Construct FQDN
-	name := r.Name
-	if name == "@" || name == "" {
-		name = zone
-	} else if !strings.HasSuffix(name, ".") {
-		name = name + "." + zone
+// fromLibdnsRecord converts a libdns record to a Lua DNS API record, switching
+// on the record's concrete type to assemble the RDATA string LuaDNS expects
+// in Content.
+func fromLibdnsRecord(rec libdns.Record, zone string) (Record, error) {
+	switch r := rec.(type) {
+	case libdns.MX:
+		return Record{
+			Name:    fqdn(r.Name, zone),
+			Type:    "MX",
+			Content: fmt.Sprintf("%d %s", r.Preference, r.Target),
+			TTL:     ttlToSeconds(r.TTL),
+		}, nil
+
+	case libdns.SRV:
+		// Let SRV.RR fold Service/Transport into the underscore-prefixed name
+		// (and trim the ".@" apex suffix) instead of duplicating that logic.
+		rr := r.RR()
+		return Record{
+			Name:    fqdn(rr.Name, zone),
+			Type:    "SRV",
+			Content: rr.Data,
+			TTL:     ttlToSeconds(rr.TTL),
+		}, nil
+
+	case libdns.CAA:
+		return Record{
+			Name:    fqdn(r.Name, zone),
+			Type:    "CAA",
+			Content: fmt.Sprintf("%d %s %q", r.Flags, r.Tag, r.Value),
+			TTL:     ttlToSeconds(r.TTL),
+		}, nil
+
+	case libdns.ServiceBinding:
+		// Scheme/port are folded into the RR name (e.g. "_443._https.host"
+		// for HTTPS, "_proto.host" for SVCB); let ServiceBinding.RR do that
+		// encoding instead of duplicating it here.
+		rr := r.RR()
+		return Record{
+			Name:    fqdn(rr.Name, zone),
+			Type:    rr.Type,
+			Content: rr.Data,
+			TTL:     ttlToSeconds(rr.TTL),
+		}, nil
+
+	case libdns.Address:
+		recType := "A"
+		if r.IP.Is6() {
+			recType = "AAAA"
+		}
+		return Record{Name: fqdn(r.Name, zone), Type: recType, Content: r.IP.String(), TTL: ttlToSeconds(r.TTL)}, nil
+
+	case libdns.CNAME:
+		return Record{Name: fqdn(r.Name, zone), Type: "CNAME", Content: r.Target, TTL: ttlToSeconds(r.TTL)}, nil
+
+	case libdns.NS:
+		return Record{Name: fqdn(r.Name, zone), Type: "NS", Content: r.Target, TTL: ttlToSeconds(r.TTL)}, nil
+
+	case libdns.TXT:
+		return Record{Name: fqdn(r.Name, zone), Type: "TXT", Content: r.Text, TTL: ttlToSeconds(r.TTL)}, nil
+
+	default:
+		rr := rec.RR()
+		return Record{Name: fqdn(rr.Name, zone), Type: rr.Type, Content: rr.Data, TTL: ttlToSeconds(rr.TTL)}, nil
 	}
-	name = strings.TrimSuffix(name, ".")
+}
 
-	ttl := int(r.TTL.Seconds())
-	if ttl == 0 {
-		ttl = 3600 // Default TTL
+// matchesRecord reports whether an API record corresponds to the same
+// resource record as rr (same name and type, after normalizing both to FQDNs).
+func matchesRecord(apiRec Record, rr libdns.RR, zone string) bool {
+	return apiRec.Name == strings.TrimSuffix(fqdn(rr.Name, zone), ".") &&
+		strings.EqualFold(apiRec.Type, rr.Type)
+}
+
+// secondsToTTL converts a LuaDNS TTL (whole seconds) to a time.Duration.
+func secondsToTTL(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// ttlToSeconds converts a libdns TTL to the whole seconds LuaDNS expects,
+// defaulting to 3600 when unset.
+func ttlToSeconds(ttl time.Duration) int {
+	seconds := int(ttl.Seconds())
+	if seconds == 0 {
+		return 3600
 	}
+	return seconds
+}
 
-	return Record{
-		Name:    name,
-		Type:    r.Type,
-		Content: r.Value,
-		TTL:     ttl,
+// absoluteZoneName returns name with exactly one trailing dot, matching the
+// absolute form libdns.Zone.Name and GetRecords/DeleteZone expect, regardless
+// of whether the LuaDNS API returned it with or without one.
+func absoluteZoneName(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+// fqdn joins name onto zone to produce the fully-qualified name LuaDNS
+// expects, treating "@" or "" as the zone apex.
+func fqdn(name, zone string) string {
+	if name == "@" || name == "" {
+		return strings.TrimSuffix(zone, ".")
+	}
+	if !strings.HasSuffix(name, ".") {
+		name = name + "." + zone
 	}
+	return strings.TrimSuffix(name, ".")
 }
 
-// matchesRecord checks if an API record matches a libdns record
-func matchesRecord(apiRec Record, libRec libdns.Record, zone string) bool {
-	libAsAPI := fromLibdnsRecord(libRec, zone)
+// relativeName strips the zone suffix from an API record's fully-qualified
+// name, returning "@" for the zone apex so the result round-trips through
+// fqdn.
+func relativeName(name, zone string) string {
+	trimmedZone := strings.TrimSuffix(zone, ".")
+	trimmedName := strings.TrimSuffix(name, ".")
+	if trimmedName == trimmedZone {
+		return "@"
+	}
+	name = strings.TrimSuffix(trimmedName, "."+trimmedZone)
+	return strings.TrimSuffix(name, ".")
+}
 
-	return apiRec.Name == libAsAPI.Name &&
-		apiRec.Type == libAsAPI.Type
+// splitSRVName splits an SRV record's relative name of the form
+// "_service._transport.host" into its three components. A record owned
+// directly by the zone apex has no host segment at all (e.g.
+// "_sip._tcp"), which is reported as host "@".
+func splitSRVName(name string) (service, transport, host string) {
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) == 2 && strings.HasPrefix(parts[0], "_") && strings.HasPrefix(parts[1], "_") {
+		return strings.TrimPrefix(parts[0], "_"), strings.TrimPrefix(parts[1], "_"), "@"
+	}
+	if len(parts) == 3 && strings.HasPrefix(parts[0], "_") && strings.HasPrefix(parts[1], "_") {
+		return strings.TrimPrefix(parts[0], "_"), strings.TrimPrefix(parts[1], "_"), parts[2]
+	}
+	return "", "", name
 }
 
 // Interface guards
@@ -285,4 +693,5 @@ var (
 	_ libdns.RecordAppender = (*Provider)(nil)
 	_ libdns.RecordSetter   = (*Provider)(nil)
 	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
 )
@@ -0,0 +1,254 @@
+package luadns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/libdns/libdns"
+)
+
+// rewriteTransport redirects every request to server's host, so a Provider
+// configured with baseURL pointing at the real API can be driven against an
+// httptest.Server instead.
+type rewriteTransport struct {
+	server *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.server.Scheme
+	req.URL.Host = t.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// fakeLuaDNS is a minimal in-memory stand-in for the Lua DNS API, enough to
+// drive zone lookups and record CRUD for reconciliation tests.
+type fakeLuaDNS struct {
+	mu         sync.Mutex
+	nextID     int
+	zoneID     int
+	zone       string
+	records    map[int]Record
+	lastImport *zoneImportRequest
+}
+
+func newFakeLuaDNS(zone string, records []Record) *fakeLuaDNS {
+	f := &fakeLuaDNS{zoneID: 1, zone: zone, records: make(map[int]Record)}
+	for _, r := range records {
+		f.nextID++
+		r.ID = f.nextID
+		r.ZoneID = f.zoneID
+		f.records[r.ID] = r
+	}
+	return f
+}
+
+func (f *fakeLuaDNS) server(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v1/zones", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("unexpected method %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode([]Zone{{ID: f.zoneID, Name: f.zone}})
+	})
+
+	mux.HandleFunc("/v1/zones/1/records", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			var list []Record
+			for _, rec := range f.records {
+				list = append(list, rec)
+			}
+			json.NewEncoder(w).Encode(list)
+		case http.MethodPost:
+			var rec Record
+			if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+				t.Fatalf("decode create body: %v", err)
+			}
+			f.nextID++
+			rec.ID = f.nextID
+			rec.ZoneID = f.zoneID
+			f.records[rec.ID] = rec
+			json.NewEncoder(w).Encode(rec)
+		default:
+			t.Fatalf("unexpected method %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/v1/zones/1/records/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/v1/zones/1/records/"))
+		if err != nil {
+			t.Fatalf("parse record id from %s: %v", r.URL.Path, err)
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			var rec Record
+			if err := json.NewDecoder(r.Body).Decode(&rec); err != nil {
+				t.Fatalf("decode update body: %v", err)
+			}
+			rec.ID = id
+			rec.ZoneID = f.zoneID
+			f.records[id] = rec
+			json.NewEncoder(w).Encode(rec)
+		case http.MethodDelete:
+			delete(f.records, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Fatalf("unexpected method %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/v1/zones/1/records/import", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected method %s %s", r.Method, r.URL.Path)
+		}
+
+		var req zoneImportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode import body: %v", err)
+		}
+
+		f.mu.Lock()
+		f.lastImport = &req
+		f.mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func newTestProvider(t *testing.T, f *fakeLuaDNS) *Provider {
+	server := f.server(t)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse server URL: %v", err)
+	}
+
+	return &Provider{
+		Email:      "test@example.com",
+		APIKey:     "key",
+		HTTPClient: &http.Client{Transport: rewriteTransport{server: serverURL}},
+	}
+}
+
+func TestSetRecordsPreservesMultiValueRRset(t *testing.T) {
+	const zone = "example.com."
+
+	f := newFakeLuaDNS("example.com", []Record{
+		{Name: "_acme-challenge.example.com", Type: "TXT", Content: "old1", TTL: 300},
+		{Name: "_acme-challenge.example.com", Type: "TXT", Content: "old2", TTL: 300},
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300},
+	})
+	p := newTestProvider(t, f)
+
+	updated, err := p.SetRecords(context.Background(), zone, []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "new1", TTL: secondsToTTL(300)},
+		libdns.TXT{Name: "_acme-challenge", Text: "new2", TTL: secondsToTTL(300)},
+	})
+	if err != nil {
+		t.Fatalf("SetRecords: %v", err)
+	}
+	if len(updated) != 2 {
+		t.Fatalf("got %d updated records, want 2", len(updated))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var challengeContents []string
+	for _, rec := range f.records {
+		if rec.Name != "_acme-challenge.example.com" {
+			continue
+		}
+		challengeContents = append(challengeContents, rec.Content)
+	}
+	if len(challengeContents) != 2 {
+		t.Fatalf("zone has %d _acme-challenge records, want 2 (RRset was collapsed): %v", len(challengeContents), challengeContents)
+	}
+
+	// The unrelated www A record must survive untouched.
+	found := false
+	for _, rec := range f.records {
+		if rec.Name == "www.example.com" && rec.Content == "192.0.2.1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("unrelated www A record was removed or modified")
+	}
+}
+
+func TestDeleteRecordsMatchesByContent(t *testing.T) {
+	const zone = "example.com."
+
+	f := newFakeLuaDNS("example.com", []Record{
+		{Name: "_acme-challenge.example.com", Type: "TXT", Content: "keepme", TTL: 300},
+		{Name: "_acme-challenge.example.com", Type: "TXT", Content: "deleteme", TTL: 300},
+	})
+	p := newTestProvider(t, f)
+
+	deleted, err := p.DeleteRecords(context.Background(), zone, []libdns.Record{
+		libdns.TXT{Name: "_acme-challenge", Text: "deleteme", TTL: secondsToTTL(300)},
+	})
+	if err != nil {
+		t.Fatalf("DeleteRecords: %v", err)
+	}
+	if len(deleted) != 1 {
+		t.Fatalf("got %d deleted records, want 1", len(deleted))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.records) != 1 {
+		t.Fatalf("zone has %d records left, want 1", len(f.records))
+	}
+	for _, rec := range f.records {
+		if rec.Content != "keepme" {
+			t.Errorf("remaining record has Content %q, want %q", rec.Content, "keepme")
+		}
+	}
+}
+
+func TestApplyZoneScriptImportsRenderedZoneFile(t *testing.T) {
+	const zone = "example.com."
+	const rendered = "example.com. 300 IN A 192.0.2.1\n"
+
+	f := newFakeLuaDNS("example.com", nil)
+	p := newTestProvider(t, f)
+
+	if err := p.ApplyZoneScript(context.Background(), zone, rendered); err != nil {
+		t.Fatalf("ApplyZoneScript: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.lastImport == nil {
+		t.Fatal("no import request reached the fake API")
+	}
+	if f.lastImport.Content != rendered {
+		t.Errorf("import Content = %q, want %q", f.lastImport.Content, rendered)
+	}
+	if !f.lastImport.Replace {
+		t.Error("import Replace = false, want true")
+	}
+}
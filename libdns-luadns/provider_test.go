@@ -0,0 +1,162 @@
+package luadns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+func TestToLibdnsRecordRoundTrip(t *testing.T) {
+	const zone = "example.com."
+
+	tests := []struct {
+		name string
+		rec  Record
+	}{
+		{"A", Record{Name: "www.example.com", Type: "A", Content: "192.0.2.1", TTL: 300}},
+		{"CNAME", Record{Name: "alias.example.com", Type: "CNAME", Content: "target.example.com", TTL: 300}},
+		{"MX apex", Record{Name: "example.com", Type: "MX", Content: "10 mail.example.com", TTL: 3600}},
+		{"SRV", Record{Name: "_sip._tcp.example.com", Type: "SRV", Content: "10 20 5060 sip.example.com", TTL: 3600}},
+		{"CAA apex", Record{Name: "example.com", Type: "CAA", Content: `0 issue "letsencrypt.org"`, TTL: 3600}},
+		{
+			"CAA with escaped quote",
+			Record{Name: "example.com", Type: "CAA", Content: `0 issue "letsencrypt.org; account=\"1234\""`, TTL: 3600},
+		},
+		{"TLSA fallback", Record{Name: "_443._tcp.example.com", Type: "TLSA", Content: "3 1 1 abcdef", TTL: 3600}},
+		{"SSHFP fallback", Record{Name: "example.com", Type: "SSHFP", Content: "1 1 abcdef", TTL: 3600}},
+		{"HTTPS", Record{Name: "example.com", Type: "HTTPS", Content: "1 . alpn=h2,h3", TTL: 3600}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := toLibdnsRecord(tt.rec, zone)
+			if err != nil {
+				t.Fatalf("toLibdnsRecord: %v", err)
+			}
+
+			back, err := fromLibdnsRecord(rec, zone)
+			if err != nil {
+				t.Fatalf("fromLibdnsRecord: %v", err)
+			}
+
+			if back.Name != tt.rec.Name {
+				t.Errorf("Name = %q, want %q", back.Name, tt.rec.Name)
+			}
+			if back.Type != tt.rec.Type {
+				t.Errorf("Type = %q, want %q", back.Type, tt.rec.Type)
+			}
+			if back.Content != tt.rec.Content {
+				t.Errorf("Content = %q, want %q", back.Content, tt.rec.Content)
+			}
+		})
+	}
+}
+
+func TestToLibdnsRecordSRVApex(t *testing.T) {
+	rec, err := toLibdnsRecord(Record{
+		Name:    "_sip._tcp.example.com",
+		Type:    "SRV",
+		Content: "10 20 5060 sip.example.com",
+		TTL:     3600,
+	}, "example.com.")
+	if err != nil {
+		t.Fatalf("toLibdnsRecord: %v", err)
+	}
+
+	srv, ok := rec.(libdns.SRV)
+	if !ok {
+		t.Fatalf("got %T, want libdns.SRV", rec)
+	}
+	if srv.Service != "sip" || srv.Transport != "tcp" || srv.Name != "@" {
+		t.Errorf("got Service=%q Transport=%q Name=%q, want sip/tcp/@", srv.Service, srv.Transport, srv.Name)
+	}
+}
+
+func TestToLibdnsRecordCAAUnquotesValue(t *testing.T) {
+	rec, err := toLibdnsRecord(Record{
+		Name:    "example.com",
+		Type:    "CAA",
+		Content: `0 issue "letsencrypt.org; account=\"1234\""`,
+		TTL:     3600,
+	}, "example.com.")
+	if err != nil {
+		t.Fatalf("toLibdnsRecord: %v", err)
+	}
+
+	caa, ok := rec.(libdns.CAA)
+	if !ok {
+		t.Fatalf("got %T, want libdns.CAA", rec)
+	}
+
+	const want = `letsencrypt.org; account="1234"`
+	if caa.Value != want {
+		t.Errorf("Value = %q, want %q", caa.Value, want)
+	}
+}
+
+func TestMatchesRecord(t *testing.T) {
+	const zone = "example.com."
+	apiRec := Record{Name: "www.example.com", Type: "A"}
+
+	rr := libdns.RR{Name: "www", Type: "A"}
+	if !matchesRecord(apiRec, rr, zone) {
+		t.Error("expected match for same name/type")
+	}
+
+	if matchesRecord(apiRec, libdns.RR{Name: "www", Type: "AAAA"}, zone) {
+		t.Error("expected no match for different type")
+	}
+	if matchesRecord(apiRec, libdns.RR{Name: "other", Type: "A"}, zone) {
+		t.Error("expected no match for different name")
+	}
+}
+
+func TestFQDNAndRelativeName(t *testing.T) {
+	const zone = "example.com."
+
+	if got := fqdn("@", zone); got != "example.com" {
+		t.Errorf("fqdn(@) = %q, want example.com", got)
+	}
+	if got := fqdn("www", zone); got != "www.example.com" {
+		t.Errorf("fqdn(www) = %q, want www.example.com", got)
+	}
+
+	if got := relativeName("www.example.com", zone); got != "www" {
+		t.Errorf("relativeName = %q, want www", got)
+	}
+	if got := relativeName("www", zone); got != "www" {
+		t.Errorf("relativeName(already relative) = %q, want www", got)
+	}
+	if got := relativeName("example.com", zone); got != "@" {
+		t.Errorf("relativeName(apex) = %q, want @", got)
+	}
+	if got := relativeName("example.com.", zone); got != "@" {
+		t.Errorf("relativeName(apex, trailing dot) = %q, want @", got)
+	}
+
+	if got := fqdn(relativeName("example.com", zone), zone); got != "example.com" {
+		t.Errorf("fqdn(relativeName(apex)) = %q, want example.com (round-trip)", got)
+	}
+}
+
+func TestAbsoluteZoneName(t *testing.T) {
+	if got := absoluteZoneName("example.com"); got != "example.com." {
+		t.Errorf("absoluteZoneName(no dot) = %q, want example.com.", got)
+	}
+	if got := absoluteZoneName("example.com."); got != "example.com." {
+		t.Errorf("absoluteZoneName(already absolute) = %q, want example.com.", got)
+	}
+}
+
+func TestTTLConversion(t *testing.T) {
+	if got := ttlToSeconds(0); got != 3600 {
+		t.Errorf("ttlToSeconds(0) = %d, want 3600 default", got)
+	}
+	if got := ttlToSeconds(90 * time.Second); got != 90 {
+		t.Errorf("ttlToSeconds(90s) = %d, want 90", got)
+	}
+	if got := secondsToTTL(90); got != 90*time.Second {
+		t.Errorf("secondsToTTL(90) = %v, want 90s", got)
+	}
+}
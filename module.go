@@ -1,13 +1,44 @@
 package luadns
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
 	"github.com/caddyserver/caddy/v2"
 	"github.com/caddyserver/caddy/v2/caddyconfig/caddyfile"
 	"github.com/libdns/luadns"
+	"go.uber.org/zap"
+)
+
+// appliedZoneFiles tracks, per account+zone, the hash of the zone file
+// content last imported by this process. Caddy reprovisions every module on
+// each config reload, but most reloads don't touch zone_file, so without
+// this a no-op config change would still re-import the zone file and block
+// provisioning on a network call every time.
+var (
+	appliedZoneFilesMu sync.Mutex
+	appliedZoneFiles   = map[string][32]byte{}
 )
 
 // Provider lets Caddy read and manipulate DNS records hosted by Lua DNS.
-type Provider struct{ *luadns.Provider }
+type Provider struct {
+	*luadns.Provider
+
+	// Zone is the zone the zone_file subdirective applies to.
+	Zone string `json:"zone,omitempty"`
+
+	// ZoneFile is a path to a BIND-format zone file, or the zone file
+	// contents themselves inline, imported into Zone on provision. This is
+	// the rendered output of a LuaDNS Lua zone script, not the Lua source
+	// itself — LuaDNS doesn't run Lua scripts server-side over its public
+	// API.
+	ZoneFile string `json:"zone_file,omitempty"`
+}
 
 func init() {
 	caddy.RegisterModule(Provider{})
@@ -17,7 +48,7 @@ func init() {
 func (Provider) CaddyModule() caddy.ModuleInfo {
 	return caddy.ModuleInfo{
 		ID:  "dns.providers.luadns",
-		New: func() caddy.Module { return &Provider{new(luadns.Provider)} },
+		New: func() caddy.Module { return &Provider{Provider: new(luadns.Provider)} },
 	}
 }
 
@@ -26,19 +57,82 @@ func (p *Provider) Provision(ctx caddy.Context) error {
 	repl := caddy.NewReplacer()
 	p.Provider.Email = repl.ReplaceAll(p.Provider.Email, "")
 	p.Provider.APIKey = repl.ReplaceAll(p.Provider.APIKey, "")
+
+	logger := ctx.Logger().Named("luadns")
+	p.Provider.OnRequest = func(method, path string) {
+		logger.Debug("luadns api request", zap.String("method", method), zap.String("path", path))
+	}
+	p.Provider.OnResponse = func(method, path string, statusCode int, duration time.Duration, err error) {
+		if err != nil {
+			logger.Error("luadns api request failed",
+				zap.String("method", method), zap.String("path", path), zap.Duration("duration", duration), zap.Error(err))
+			return
+		}
+		logger.Debug("luadns api response",
+			zap.String("method", method), zap.String("path", path), zap.Int("status", statusCode), zap.Duration("duration", duration))
+	}
+
+	if p.ZoneFile != "" {
+		zone := repl.ReplaceAll(p.Zone, "")
+		zoneFile, err := loadZoneFile(repl.ReplaceAll(p.ZoneFile, ""))
+		if err != nil {
+			return fmt.Errorf("loading zone file: %w", err)
+		}
+
+		key := p.Provider.APIKey + "\x00" + zone
+		hash := sha256.Sum256([]byte(zoneFile))
+
+		appliedZoneFilesMu.Lock()
+		unchanged := appliedZoneFiles[key] == hash
+		appliedZoneFilesMu.Unlock()
+
+		if !unchanged {
+			if err := p.Provider.ApplyZoneScript(ctx, zone, zoneFile); err != nil {
+				return fmt.Errorf("importing zone file: %w", err)
+			}
+			appliedZoneFilesMu.Lock()
+			appliedZoneFiles[key] = hash
+			appliedZoneFilesMu.Unlock()
+		}
+	}
+
 	return nil
 }
 
+// loadZoneFile returns the contents of pathOrInline: if it names an existing
+// file, its contents are returned, otherwise pathOrInline is treated as the
+// zone file itself. An existing file that cannot be read is an error.
+func loadZoneFile(pathOrInline string) (string, error) {
+	data, err := os.ReadFile(pathOrInline)
+	if err != nil {
+		// Inline zone file contents are usually too long to be a file name.
+		if os.IsNotExist(err) || errors.Is(err, syscall.ENAMETOOLONG) {
+			return pathOrInline, nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
 // UnmarshalCaddyfile sets up the DNS provider from Caddyfile tokens. Syntax:
 //
 //	luadns {
 //	    email <email>
 //	    api_key <api_key>
+//	    zone <zone>
+//	    zone_file <path-or-inline>
 //	}
 //
 // or inline:
 //
 //	luadns <email> <api_key>
+//
+// zone_file declares the zone's contents as a BIND-format zone file: the
+// argument is read as a file path if it exists, otherwise treated as the
+// zone file contents themselves. This is meant for a file rendered from a
+// LuaDNS Lua zone script, not the Lua source itself — LuaDNS doesn't run Lua
+// scripts server-side over its public API. zone_file requires zone to be
+// set.
 func (p *Provider) UnmarshalCaddyfile(d *caddyfile.Dispenser) error {
 	for d.Next() {
 		// Support inline format: luadns <email> <api_key>
@@ -75,6 +169,28 @@ func (p *Provider) UnmarshalCaddyfile(d *caddyfile.Dispenser) error {
 				if d.NextArg() {
 					return d.ArgErr()
 				}
+			case "zone":
+				if p.Zone != "" {
+					return d.Err("zone already set")
+				}
+				if !d.NextArg() {
+					return d.ArgErr()
+				}
+				p.Zone = d.Val()
+				if d.NextArg() {
+					return d.ArgErr()
+				}
+			case "zone_file":
+				if p.ZoneFile != "" {
+					return d.Err("zone_file already set")
+				}
+				if !d.NextArg() {
+					return d.ArgErr()
+				}
+				p.ZoneFile = d.Val()
+				if d.NextArg() {
+					return d.ArgErr()
+				}
 			default:
 				return d.Errf("unrecognized subdirective '%s'", d.Val())
 			}
@@ -88,6 +204,9 @@ func (p *Provider) UnmarshalCaddyfile(d *caddyfile.Dispenser) error {
 	if p.Provider.APIKey == "" {
 		return d.Err("missing API key")
 	}
+	if p.ZoneFile != "" && p.Zone == "" {
+		return d.Err("zone_file requires zone to be set")
+	}
 
 	return nil
 }